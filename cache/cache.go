@@ -0,0 +1,225 @@
+// Package cache provides an in-process caching layer for data pulled from the
+// Groupie Trackers upstream API. It coalesces concurrent fetches for the same
+// key, honours per-key TTLs, and can refresh entries in the background before
+// they expire so requests rarely see a cold cache.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+)
+
+// Entry is a single cached value along with the metadata needed to answer
+// conditional HTTP requests (ETag / Last-Modified).
+type Entry struct {
+	Data         []byte
+	ETag         string
+	LastModified time.Time
+	Expires      time.Time
+}
+
+// Fresh reports whether the entry is still within its TTL.
+func (e Entry) Fresh() bool {
+	return time.Now().Before(e.Expires)
+}
+
+// FetchFunc fetches a fresh value for a cache key. The returned value is
+// JSON-marshalled before being stored.
+type FetchFunc func() (interface{}, error)
+
+// Store is an in-memory, TTL-based cache with singleflight request
+// coalescing. The zero value is not usable; construct one with NewStore.
+type Store struct {
+	mu      sync.RWMutex
+	entries map[string]Entry
+	group   group
+
+	hits   uint64
+	misses uint64
+}
+
+// NewStore creates an empty Store.
+func NewStore() *Store {
+	return &Store{entries: make(map[string]Entry)}
+}
+
+// Get returns the cached entry for key, if present and not expired.
+func (s *Store) Get(key string) (Entry, bool) {
+	s.mu.RLock()
+	entry, ok := s.entries[key]
+	s.mu.RUnlock()
+	if !ok || !entry.Fresh() {
+		return Entry{}, false
+	}
+	return entry, true
+}
+
+// set stores data under key with the given ttl and returns the resulting
+// entry, computing an ETag from the content hash.
+func (s *Store) set(key string, data []byte, ttl time.Duration) Entry {
+	sum := sha256.Sum256(data)
+	entry := Entry{
+		Data:         data,
+		ETag:         `"` + hex.EncodeToString(sum[:8]) + `"`,
+		LastModified: time.Now(),
+		Expires:      time.Now().Add(ttl),
+	}
+	s.mu.Lock()
+	s.entries[key] = entry
+	s.mu.Unlock()
+	return entry
+}
+
+// GetOrFetch returns the cached entry for key, fetching and storing a fresh
+// one if it is missing or expired. Concurrent calls for the same key are
+// coalesced into a single call to fetch. Cache-hit/miss counters are logged
+// per call.
+func (s *Store) GetOrFetch(key string, ttl time.Duration, fetch FetchFunc) (Entry, error) {
+	if entry, ok := s.Get(key); ok {
+		s.recordHit(key)
+		return entry, nil
+	}
+	s.recordMiss(key)
+	return s.refresh(key, ttl, fetch)
+}
+
+// refresh fetches a fresh value for key and stores it, unconditionally -
+// unlike GetOrFetch it doesn't check whether an unexpired entry already
+// exists, so callers that need to force a refresh (e.g. StartRefresher,
+// proactively re-pulling before expiry) can use it directly. Concurrent
+// refreshes for the same key are still coalesced.
+func (s *Store) refresh(key string, ttl time.Duration, fetch FetchFunc) (Entry, error) {
+	v, err := s.group.do(key, func() (interface{}, error) {
+		value, err := fetch()
+		if err != nil {
+			return Entry{}, err
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return Entry{}, err
+		}
+		return s.set(key, data, ttl), nil
+	})
+	if err != nil {
+		return Entry{}, err
+	}
+	return v.(Entry), nil
+}
+
+// Unmarshal decodes entry.Data into out.
+func (e Entry) Unmarshal(out interface{}) error {
+	return json.Unmarshal(e.Data, out)
+}
+
+// Combine derives a single Entry representing a response whose body was
+// assembled from several independently-cached entries (e.g. an artist page
+// built from artist-core, dates, locations and relations entries fetched
+// through different keys with different TTLs). Its ETag changes whenever
+// any of entries changes, and its LastModified is the most recent of them -
+// using just one of the underlying entries' ETag would let a client's
+// If-None-Match get a stale 304 once the others have refreshed independently.
+func Combine(entries ...Entry) Entry {
+	h := sha256.New()
+	var lastModified time.Time
+	for _, e := range entries {
+		h.Write([]byte(e.ETag))
+		if e.LastModified.After(lastModified) {
+			lastModified = e.LastModified
+		}
+	}
+	return Entry{
+		ETag:         `"` + hex.EncodeToString(h.Sum(nil)[:8]) + `"`,
+		LastModified: lastModified,
+	}
+}
+
+func (s *Store) recordHit(key string) {
+	s.mu.Lock()
+	s.hits++
+	hits, misses := s.hits, s.misses
+	s.mu.Unlock()
+	log.Printf("cache hit key=%q hits=%d misses=%d", key, hits, misses)
+}
+
+func (s *Store) recordMiss(key string) {
+	s.mu.Lock()
+	s.misses++
+	hits, misses := s.hits, s.misses
+	s.mu.Unlock()
+	log.Printf("cache miss key=%q hits=%d misses=%d", key, hits, misses)
+}
+
+// StartRefresher seeds key synchronously with one fetch, then refreshes it
+// in the background on a fixed interval of ttl-refreshBefore, refreshing a
+// bit before it would otherwise expire so callers rarely observe a cold
+// cache. If refreshBefore is not shorter than ttl, the interval is ttl
+// itself and the refresh happens at expiry rather than before it. The
+// synchronous seed means callers depending on key being populated (e.g. an
+// autocomplete index built from it) can rely on that being true as soon as
+// StartRefresher returns, rather than waiting out a full interval for
+// time.Ticker's first tick. It must be called once per key; the background
+// loop runs until the process exits.
+func (s *Store) StartRefresher(key string, ttl, refreshBefore time.Duration, fetch FetchFunc) error {
+	interval := ttl - refreshBefore
+	if interval <= 0 {
+		interval = ttl
+	}
+
+	if _, err := s.refresh(key, ttl, fetch); err != nil {
+		return err
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if _, err := s.refresh(key, ttl, fetch); err != nil {
+				log.Printf("cache background refresh failed key=%q: %v", key, err)
+			}
+		}
+	}()
+	return nil
+}
+
+// group coalesces concurrent calls sharing a key into a single execution of
+// fn, similar to golang.org/x/sync/singleflight. It is implemented locally
+// to avoid adding an external dependency for one small primitive.
+type group struct {
+	mu sync.Mutex
+	m  map[string]*call
+}
+
+type call struct {
+	wg  sync.WaitGroup
+	val interface{}
+	err error
+}
+
+func (g *group) do(key string, fn func() (interface{}, error)) (interface{}, error) {
+	g.mu.Lock()
+	if g.m == nil {
+		g.m = make(map[string]*call)
+	}
+	if c, ok := g.m[key]; ok {
+		g.mu.Unlock()
+		c.wg.Wait()
+		return c.val, c.err
+	}
+	c := new(call)
+	c.wg.Add(1)
+	g.m[key] = c
+	g.mu.Unlock()
+
+	c.val, c.err = fn()
+	c.wg.Done()
+
+	g.mu.Lock()
+	delete(g.m, key)
+	g.mu.Unlock()
+
+	return c.val, c.err
+}
@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetOrFetchCachesUntilTTL(t *testing.T) {
+	s := NewStore()
+	var calls int32
+	fetch := func() (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		return "value", nil
+	}
+
+	if _, err := s.GetOrFetch("k", time.Hour, fetch); err != nil {
+		t.Fatalf("GetOrFetch: %v", err)
+	}
+	if _, err := s.GetOrFetch("k", time.Hour, fetch); err != nil {
+		t.Fatalf("GetOrFetch: %v", err)
+	}
+
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("fetch called %d times, want 1 (second call should hit cache)", got)
+	}
+}
+
+func TestStartRefresherSeedsSynchronously(t *testing.T) {
+	s := NewStore()
+	var calls int32
+	fetch := func() (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		return "value", nil
+	}
+
+	if err := s.StartRefresher("k", 60*time.Millisecond, 40*time.Millisecond, fetch); err != nil {
+		t.Fatalf("StartRefresher: %v", err)
+	}
+
+	// The real key must already be populated the instant StartRefresher
+	// returns, without waiting for a background ticker to fire.
+	entry, ok := s.Get("k")
+	if !ok || len(entry.Data) == 0 {
+		t.Fatalf("real cache key not populated synchronously (fetch called %d times)", atomic.LoadInt32(&calls))
+	}
+}
+
+func TestStartRefresherKeepsRefreshingInBackground(t *testing.T) {
+	s := NewStore()
+	var calls int32
+	fetch := func() (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		return "value", nil
+	}
+
+	if err := s.StartRefresher("k", 30*time.Millisecond, 20*time.Millisecond, fetch); err != nil {
+		t.Fatalf("StartRefresher: %v", err)
+	}
+
+	deadline := time.Now().Add(200 * time.Millisecond)
+	for time.Now().Before(deadline) && atomic.LoadInt32(&calls) < 2 {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := atomic.LoadInt32(&calls); got < 2 {
+		t.Fatalf("fetch called %d times, want at least 2 (initial seed + a background refresh)", got)
+	}
+}
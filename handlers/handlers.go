@@ -2,152 +2,282 @@ package api
 
 import (
 	"encoding/json"
-	"log"
 	"net/http"
-	"path/filepath"
 	"strconv"
-	"strings"
-	"text/template"
+	"sync/atomic"
+	"time"
+
+	"music-explorer/cache"
+	"music-explorer/router"
+	"music-explorer/search"
+	"music-explorer/views"
 )
 
-var errorTemplate *template.Template
+// store holds cached responses from the Groupie Trackers upstream, keyed by
+// request URL. See cachedReadArtists and friends below.
+var store = cache.NewStore()
+
+// Per-endpoint TTLs: the artists list changes rarely, while relation/date
+// data is refreshed more eagerly since it's smaller and cheaper to re-pull.
+const (
+	artistsTTL   = 10 * time.Minute
+	artistTTL    = 5 * time.Minute
+	datesTTL     = 2 * time.Minute
+	locationsTTL = 2 * time.Minute
+	relationsTTL = 2 * time.Minute
+
+	// refreshBefore is how far ahead of expiry the background refresher
+	// re-pulls an entry, so requests rarely observe a cold cache.
+	refreshBefore = 30 * time.Second
+)
 
-/*
-Init initializes the error template for the application.
-It attempts to parse the error.html template file. If parsing fails,
-it creates a simple fallback template to ensure error rendering.
-This function should be called once at the start of the application.
-*/
-func Init() {
-	var err error
-	errorTemplate, err = template.ParseFiles("template/error.html")
+// StartArtistsRefresher seeds the artists-list cache (and the suggest trie
+// built from it) synchronously, then keeps re-pulling it in the background
+// shortly before it would otherwise expire. Call once at startup alongside
+// Init, and check the returned error: after a failure here no background
+// refresh runs, and /api/suggest serves "not ready" until ArtistsHandler or
+// SearchAPIHandler fetches the artist list through cachedReadArtists.
+func StartArtistsRefresher(baseURL string) error {
+	return store.StartRefresher("artists:"+baseURL, artistsTTL, refreshBefore, func() (interface{}, error) {
+		artists, err := ReadArtists(baseURL)
+		if err != nil {
+			return nil, err
+		}
+		RebuildSuggestTrie(artists)
+		return artists, nil
+	})
+}
+
+// cachedReadArtists fetches the artist list through the cache, returning the
+// decoded artists alongside the cache entry so callers can set ETag /
+// Last-Modified headers. It also rebuilds the suggest trie on every fetch
+// that actually hits the upstream, so /api/suggest stays in sync even if
+// StartArtistsRefresher's background loop isn't what populated this entry.
+func cachedReadArtists(url string) ([]Artist, cache.Entry, error) {
+	fetched := false
+	entry, err := store.GetOrFetch("artists:"+url, artistsTTL, func() (interface{}, error) {
+		fetched = true
+		return ReadArtists(url)
+	})
+	if err != nil {
+		return nil, entry, err
+	}
+	var artists []Artist
+	if err := entry.Unmarshal(&artists); err != nil {
+		return nil, entry, err
+	}
+	if fetched {
+		RebuildSuggestTrie(artists)
+	}
+	return artists, entry, nil
+}
+
+// cachedReadArtist fetches a single artist through the cache.
+func cachedReadArtist(baseURL, id string) (Artist, cache.Entry, error) {
+	entry, err := store.GetOrFetch("artist:"+baseURL+id, artistTTL, func() (interface{}, error) {
+		return ReadArtist(baseURL, id)
+	})
+	if err != nil {
+		return Artist{}, entry, err
+	}
+	var artist Artist
+	if err := entry.Unmarshal(&artist); err != nil {
+		return Artist{}, entry, err
+	}
+	return artist, entry, nil
+}
+
+// cachedReadDate fetches an artist's concert dates through the cache.
+func cachedReadDate(baseURL, id string) (DateEntry, cache.Entry, error) {
+	entry, err := store.GetOrFetch("date:"+baseURL+id, datesTTL, func() (interface{}, error) {
+		return ReadDate(baseURL, id)
+	})
 	if err != nil {
-		// log.Printf("Warning: Error parsing error template: %v", err)
-		// Create a simple fallback template
-		errorTemplate = template.Must(template.New("error").Parse(`
-            <html><body>
-            <h1>Error {{.Code}}</h1>
-            <p>{{.Message}}</p>
-            </body></html>
-        `))
-		//  log.Println("Error parsing, using fallback template")
+		return DateEntry{}, entry, err
+	}
+	var date DateEntry
+	if err := entry.Unmarshal(&date); err != nil {
+		return DateEntry{}, entry, err
 	}
+	return date, entry, nil
+}
+
+// cachedReadLocation fetches an artist's locations through the cache.
+func cachedReadLocation(baseURL, id string) (Location, cache.Entry, error) {
+	entry, err := store.GetOrFetch("location:"+baseURL+id, locationsTTL, func() (interface{}, error) {
+		return ReadLocation(baseURL, id)
+	})
+	if err != nil {
+		return Location{}, entry, err
+	}
+	var location Location
+	if err := entry.Unmarshal(&location); err != nil {
+		return Location{}, entry, err
+	}
+	return location, entry, nil
+}
+
+// cachedReadRelations fetches an artist's relations through the cache.
+func cachedReadRelations(baseURL, id string) (Relation, cache.Entry, error) {
+	entry, err := store.GetOrFetch("relation:"+baseURL+id, relationsTTL, func() (interface{}, error) {
+		return ReadRelations(baseURL, id)
+	})
+	if err != nil {
+		return Relation{}, entry, err
+	}
+	var relation Relation
+	if err := entry.Unmarshal(&relation); err != nil {
+		return Relation{}, entry, err
+	}
+	return relation, entry, nil
+}
+
+// writeCacheHeaders sets ETag/Last-Modified from entry and, if the request's
+// If-None-Match matches, writes a 304 and returns true so the caller can skip
+// rendering the body.
+func writeCacheHeaders(w http.ResponseWriter, r *http.Request, entry cache.Entry) bool {
+	w.Header().Set("ETag", entry.ETag)
+	w.Header().Set("Last-Modified", entry.LastModified.UTC().Format(http.TimeFormat))
+	if inm := r.Header.Get("If-None-Match"); inm != "" && inm == entry.ETag {
+		w.WriteHeader(http.StatusNotModified)
+		return true
+	}
+	return false
 }
 
 /*
-renderError handles the rendering of error pages.
-It sets the HTTP status code, executes the error template with the provided status and message,
-and logs any errors that occur during template execution.
-Parameters:
-  - w: http.ResponseWriter to write the response
-  - status: HTTP status code for the error
-  - message: Error message to display
+Init parses every page template once via the views package. It must be
+called once at application startup, before any handler runs.
 */
-type SearchResult struct {
-	Name string `json:"name"`
-	Type string `json:"type"`
+func Init() error {
+	return views.Init(false)
 }
 
-// SearchHandler handles search requests for "first album" and "creation date".
-func SearchHandler(w http.ResponseWriter, r *http.Request) {
+// toSearchArtists adapts the API's artist shape to search.Artist, the
+// minimal shape the search package itself depends on.
+func toSearchArtists(artists []Artist) []search.Artist {
+	out := make([]search.Artist, len(artists))
+	for i, a := range artists {
+		out[i] = search.Artist{
+			ID:           a.ID,
+			Name:         a.Name,
+			Members:      a.Members,
+			Locations:    a.Locations,
+			ConcertDates: a.ConcertDates,
+			FirstAlbum:   a.FirstAlbum,
+			CreationDate: a.CreationDate,
+		}
+	}
+	return out
+}
+
+// suggestTrie backs SuggestHandler and is rebuilt whenever the artists cache
+// is refreshed, so autocomplete never scans the full artist list.
+var suggestTrie atomic.Pointer[search.Trie]
+
+// RebuildSuggestTrie rebuilds the autocomplete trie from the given artists.
+// It is called once at startup and again on every background cache refresh.
+func RebuildSuggestTrie(artists []Artist) {
+	suggestTrie.Store(search.BuildTrie(toSearchArtists(artists)))
+}
+
+// SearchAPIHandler serves GET /api/search, the single typed search endpoint
+// that replaces the old query/first-album-date split between SearchHandler
+// and ArtistsHandler's inline searchArtists.
+func SearchAPIHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		renderError(w, http.StatusMethodNotAllowed, "Wrong method")
 		return
 	}
 
-	query := r.URL.Query().Get("query")
+	query := r.URL.Query().Get("q")
 	if query == "" {
-		renderError(w, http.StatusBadRequest, "Query parameter is missing")
+		renderError(w, http.StatusBadRequest, "q parameter is missing")
 		return
 	}
 
-	lowerQuery := strings.ToLower(query)
-
-	// Fetch artist data
-	artists, err := ReadArtists("https://groupietrackers.herokuapp.com/api/artists")
+	// Note: unlike ArtistsHandler/ArtistHandler, this endpoint's response
+	// depends on q/limit/offset, not just the artist-list cache entry, so
+	// the artist-list ETag isn't a valid representation identity here and
+	// conditional-GET handling is intentionally skipped.
+	artists, _, err := cachedReadArtists("https://groupietrackers.herokuapp.com/api/artists")
 	if err != nil {
 		renderError(w, http.StatusInternalServerError, "Error fetching artist data")
 		return
 	}
 
-	var results []SearchResult
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
 
-	// Search within CreationDate and FirstAlbum
-	for _, artist := range artists {
-		// Check FirstAlbum as a string
-		if strings.Contains(strings.ToLower(artist.FirstAlbum), lowerQuery) {
-			results = append(results, SearchResult{
-				Name: artist.Name,
-				Type: "first album date",
-			})
-		}
-		// Check CreationDate by converting it to a string
-		if strings.Contains(strconv.Itoa(artist.CreationDate), lowerQuery) {
-			results = append(results, SearchResult{
-				Name: artist.Name,
-				Type: "creation date",
-			})
-		}
+	response := search.Search(toSearchArtists(artists), query, search.Options{Limit: limit, Offset: offset})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// SuggestHandler serves GET /api/suggest, returning autocomplete candidates
+// for the given prefix from the prebuilt trie in O(prefix length).
+func SuggestHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		renderError(w, http.StatusMethodNotAllowed, "Wrong method")
+		return
+	}
+
+	query := r.URL.Query().Get("q")
+	if query == "" {
+		renderError(w, http.StatusBadRequest, "q parameter is missing")
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 {
+		limit = 10
+	}
+
+	trie := suggestTrie.Load()
+	if trie == nil {
+		renderError(w, http.StatusServiceUnavailable, "Suggestions are not ready yet")
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(results)
+	json.NewEncoder(w).Encode(struct {
+		Suggestions []search.Suggestion `json:"suggestions"`
+	}{Suggestions: trie.PrefixSearch(query, limit)})
 }
 
+// renderError renders the shared error page for status/message.
 func renderError(w http.ResponseWriter, status int, message string) {
-	Init()
-	w.WriteHeader(status)
-	err := errorTemplate.Execute(w, struct {
-		Code    int
-		Message string
-	}{
-		Code:    status,
-		Message: message,
-	})
-	if err != nil {
-		log.Printf("Error rendering error template: %v", err)
-	}
+	views.RenderError(w, status, message)
 }
 
 /*
-HomeHandler manages requests to the home page of the application.
-It checks if the requested path is the root ("/") and if the HTTP method is GET.
-If these conditions are not met, it renders appropriate error pages.
-Otherwise, it parses and executes the home.html template.
+HomeHandler manages requests to the home page of the application. It is
+registered with router.Router.HandleExact("/", HomeHandler), so the path is
+already known to be exactly "/"; this still checks the method and renders
+the home.html template.
 
 Parameters:
   - w: http.ResponseWriter to write the response
   - r: *http.Request containing the request details
 */
 func HomeHandler(w http.ResponseWriter, r *http.Request) {
-	if r.URL.Path != "/" {
-		renderError(w, http.StatusNotFound, "The Page you're trying to acess is unavailable")
-		return
-	}
-
 	if r.Method != http.MethodGet {
 		renderError(w, http.StatusMethodNotAllowed, "Wrong method")
 		return
 	}
 
-	// Parse the homepage template
-	temp, err := template.ParseFiles("template/home.html") // Ensure you have home.html in the template directory
-	if err != nil {
-		renderError(w, http.StatusInternalServerError, "Error loading template")
-		return
-	}
-
-	// Execute the template and write to the response
-	err = temp.Execute(w, nil) // No data is passed to the homepage template
-	if err != nil {
+	if err := views.Render(w, "home", nil); err != nil {
 		renderError(w, http.StatusInternalServerError, "Error executing template")
 	}
 }
 
 /*
-ArtistsHandler manages requests to the artists listing page.
-It verifies the correct URL path and HTTP method, then fetches and displays
-the list of artists. If any errors occur during this process, it renders
+ArtistsHandler manages requests to the artists listing page. It is
+registered with router.Router.HandleExact("/artists", ArtistsHandler), so
+the path is already known to be exactly "/artists"; this still checks the
+method, fetches and displays the list of artists, or serves a JSON search
+response for "?q=". If any errors occur during this process, it renders
 appropriate error pages.
 
 Parameters:
@@ -155,85 +285,37 @@ Parameters:
   - r: *http.Request containing the request details
 */
 func ArtistsHandler(w http.ResponseWriter, r *http.Request) {
-	if r.URL.Path != "/artists" && r.URL.Path != "/artists/" {
-		renderError(w, http.StatusNotFound, "The Page you're trying to access is unavailable")
-		return
-	}
-
 	if r.Method != http.MethodGet {
 		renderError(w, http.StatusMethodNotAllowed, "Wrong method")
 		return
 	}
 
 	// Fetch all artists
-	result, err := ReadArtists("https://groupietrackers.herokuapp.com/api/artists")
+	result, entry, err := cachedReadArtists("https://groupietrackers.herokuapp.com/api/artists")
 	if err != nil {
 		renderError(w, http.StatusInternalServerError, "Error fetching artists")
 		return
 	}
 
-	// Check if it's a search request
+	// Check if it's a search request; delegate to the unified search
+	// package instead of the old hand-rolled field-by-field scan.
 	query := r.URL.Query().Get("q")
 	if query != "" {
-		// Perform search
-		searchResults := searchArtists(result, query)
+		response := search.Search(toSearchArtists(result), query, search.Options{})
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(searchResults)
+		json.NewEncoder(w).Encode(response)
 		return
 	}
 
-	// If not a search request, render the full artists page
-	templatePath := filepath.Join("template", "artists.html")
-	temp1, err := template.ParseFiles(templatePath)
-	if err != nil {
-		renderError(w, http.StatusInternalServerError, "Error loading template")
+	if writeCacheHeaders(w, r, entry) {
 		return
 	}
 
-	err = temp1.Execute(w, result)
-	if err != nil {
+	// If not a search request, render the full artists page
+	if err := views.Render(w, "artists", result); err != nil {
 		renderError(w, http.StatusInternalServerError, "Error executing template")
 	}
 }
-func searchArtists(artists []Artist, query string) []Artist {
-	var results []Artist
-	query = strings.ToLower(query)
-
-	for _, artist := range artists {
-		// Check if the artist's name matches
-		if strings.Contains(strings.ToLower(artist.Name), query) {
-			results = append(results, artist)
-			continue
-		}
-
-		// Check if any member matches
-		for _, member := range artist.Members {
-			if strings.Contains(strings.ToLower(member), query) {
-				results = append(results, artist)
-				break // No need to check other members if a match is found
-			}
-		}
-
-		// Check location
-		if strings.Contains(strings.ToLower(artist.Locations), query) {
-			results = append(results, artist)
-			continue
-		}
-
-		// Check first album date
-		if strings.Contains(artist.ConcertDates, query) {
-			results = append(results, artist)
-			continue
-		}
-
-		if creationYear, err := strconv.Atoi(query); err == nil && artist.CreationDate == creationYear {
-			results = append(results, artist)
-			continue
-		}
-	}
-
-	return results
-}
 
 type ArtistData struct {
 	Artist       Artist    `json:"artist"`
@@ -245,59 +327,61 @@ type ArtistData struct {
 	ConcertDates string    `json:"concertDates"`
 }
 
+// ArtistHandler is registered against the declarative pattern "/artist/{id}"
+// (see router.Router.GET), which both dispatches only GET requests here and
+// extracts {id} for us, so the handler no longer parses r.URL.Path itself.
 func ArtistHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		renderError(w, http.StatusMethodNotAllowed, "Wrong method")
-		return
-	}
-
-	if !strings.HasPrefix(r.URL.Path, "/artist/") || len(strings.Split(r.URL.Path, "/")) != 3 {
+	idNum, ok := router.IntParam(r, "id")
+	if !ok {
 		renderError(w, http.StatusNotFound, "The Page you're trying to access is unavailable")
 		return
 	}
+	id := strconv.Itoa(idNum)
 
-	id1 := strings.Split(r.URL.Path, "/")
-	if len(id1) < 3 {
-		renderError(w, http.StatusBadRequest, "Artist ID not found")
-		return
-	}
-
-	id := id1[len(id1)-1]
-
-	// Check for the section query parameter
-	section := r.URL.Query().Get("section")
-	if section != "" && section != "locations" && section != "dates" && section != "relations" && section != "all" {
+	if err := router.ValidateQuery(r, router.QueryParam{
+		Name:    "section",
+		Allowed: []string{"locations", "dates", "relations", "all"},
+	}); err != nil {
 		renderError(w, http.StatusNotFound, "The section you're trying to access is unavailable")
 		return
 	}
+	section := r.URL.Query().Get("section")
 
 	// Fetch artist details
 	baseURL := "https://groupietrackers.herokuapp.com/api/artists/"
-	artistResult, err := ReadArtist(baseURL, id)
+	artistResult, artistEntry, err := cachedReadArtist(baseURL, id)
 	if err != nil || artistResult.ID == 0 {
 		renderError(w, http.StatusNotFound, "The Page you're trying to access is unavailable")
 		return
 	}
 
 	// Fetch related data: dates, locations, relations
-	datesResult, err := ReadDate("https://groupietrackers.herokuapp.com/api/dates/", id)
+	datesResult, datesEntry, err := cachedReadDate("https://groupietrackers.herokuapp.com/api/dates/", id)
 	if err != nil {
 		renderError(w, http.StatusInternalServerError, "Error fetching dates")
 		return
 	}
 
-	locationsResult, err := ReadLocation("https://groupietrackers.herokuapp.com/api/locations/", id)
+	locationsResult, locationsEntry, err := cachedReadLocation("https://groupietrackers.herokuapp.com/api/locations/", id)
 	if err != nil {
 		renderError(w, http.StatusInternalServerError, "Error fetching locations")
 		return
 	}
 
-	relationsResult, err := ReadRelations("https://groupietrackers.herokuapp.com/api/relation/", id)
+	relationsResult, relationsEntry, err := cachedReadRelations("https://groupietrackers.herokuapp.com/api/relation/", id)
 	if err != nil {
 		renderError(w, http.StatusInternalServerError, "Error fetching relations")
 		return
 	}
 
+	// The rendered page depends on all four independently-cached entries,
+	// not just the artist-core one, so the ETag must cover all of them or a
+	// stale If-None-Match could 304 a response whose dates/locations/
+	// relations actually changed.
+	if writeCacheHeaders(w, r, cache.Combine(artistEntry, datesEntry, locationsEntry, relationsEntry)) {
+		return
+	}
+
 	// Combine all results into a single struct to pass to the template
 	artistData := ArtistData{
 		Artist:    artistResult,
@@ -307,15 +391,20 @@ func ArtistHandler(w http.ResponseWriter, r *http.Request) {
 		Section:   section, // Add this line to pass the section to the template
 	}
 
-	// Load and execute the artist template with combined data
-	temp1, err := template.ParseFiles("template/artist.html")
-	if err != nil {
-		renderError(w, http.StatusInternalServerError, "Error loading template")
+	// Intermediaries must not serve a cached full-page response to an HTMX
+	// client (or vice versa), since the body differs.
+	w.Header().Set("Vary", "HX-Request")
+
+	// HTMX requests for a specific section only want that section's markup
+	// re-rendered, not the whole document.
+	if r.Header.Get("HX-Request") == "true" && section != "" {
+		if err := views.RenderBlock(w, "artist", section, artistData); err != nil {
+			renderError(w, http.StatusInternalServerError, "Error executing template")
+		}
 		return
 	}
 
-	err = temp1.Execute(w, artistData)
-	if err != nil {
+	if err := views.Render(w, "artist", artistData); err != nil {
 		renderError(w, http.StatusInternalServerError, "Error executing template")
 	}
 }
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// gzipResponseWriter wraps http.ResponseWriter so Write calls pass through a
+// gzip.Writer transparently; handlers downstream don't need to know the
+// response is being compressed.
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	gz io.Writer
+}
+
+func (w *gzipResponseWriter) Write(b []byte) (int, error) {
+	return w.gz.Write(b)
+}
+
+// Gzip wraps next, compressing the response body whenever the client
+// advertises gzip support via Accept-Encoding. It sets Vary: Accept-Encoding
+// so caches don't serve a compressed response to a client that can't decode
+// it.
+func Gzip(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		defer gz.Close()
+		next.ServeHTTP(&gzipResponseWriter{ResponseWriter: w, gz: gz}, r)
+	})
+}
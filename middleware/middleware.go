@@ -0,0 +1,136 @@
+// Package middleware provides cross-cutting http.Handler wrappers: request
+// logging, panic recovery, gzip compression, request IDs and per-IP rate
+// limiting. Handlers themselves stay focused on a single
+// request's business logic. The logging sink is pluggable via SetLogger, so
+// operators aren't locked into the standard library logger.
+package middleware
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"music-explorer/router"
+	"music-explorer/views"
+)
+
+// responseWriter wraps http.ResponseWriter to capture the status code and
+// byte count actually written, neither of which is otherwise observable
+// from outside the handler.
+type responseWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (rw *responseWriter) WriteHeader(status int) {
+	rw.status = status
+	rw.ResponseWriter.WriteHeader(status)
+}
+
+func (rw *responseWriter) Write(b []byte) (int, error) {
+	if rw.status == 0 {
+		rw.status = http.StatusOK
+	}
+	n, err := rw.ResponseWriter.Write(b)
+	rw.bytes += n
+	return n, err
+}
+
+// LogFunc is the sink Logger and Recover write through. It matches
+// log.Printf's signature, so the standard library logger needs no adapter
+// and operators can plug in zap (SugaredLogger.Infof) or zerolog (wrapped in
+// a one-line func) instead of being stuck with log.Printf.
+type LogFunc func(format string, args ...interface{})
+
+var logf LogFunc = log.Printf
+
+// SetLogger replaces the sink used by Logger and Recover. Call it once at
+// startup, before installing the middleware, e.g.
+// middleware.SetLogger(zapSugaredLogger.Infof).
+func SetLogger(f LogFunc) {
+	logf = f
+}
+
+// logEntry is the structured record emitted per request; it's also used to
+// render the human-readable line so both formats stay in sync.
+type logEntry struct {
+	Time      string `json:"time"`
+	Method    string `json:"method"`
+	Path      string `json:"path"`
+	Status    int    `json:"status"`
+	Bytes     int    `json:"bytes"`
+	ElapsedMs int64  `json:"elapsedMs"`
+	RemoteIP  string `json:"remoteIp"`
+	UserAgent string `json:"userAgent"`
+	ArtistID  string `json:"artistId,omitempty"`
+	RequestID string `json:"requestId,omitempty"`
+}
+
+// Logger wraps next with structured request logging: method, path, status,
+// bytes written, elapsed time, remote IP, user-agent, the request ID
+// attached by RequestID (if that middleware ran first) and - for routes
+// with a router "id" param - the matched artist ID. The output format is
+// "json" or "text" (default), selected via the LOG_FORMAT environment
+// variable, so operators can redirect it into their log pipeline of choice.
+func Logger(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rw := &responseWriter{ResponseWriter: w}
+
+		next.ServeHTTP(rw, r)
+
+		entry := logEntry{
+			Time:      start.UTC().Format(time.RFC3339),
+			Method:    r.Method,
+			Path:      r.URL.Path,
+			Status:    rw.status,
+			Bytes:     rw.bytes,
+			ElapsedMs: time.Since(start).Milliseconds(),
+			RemoteIP:  clientIP(r),
+			UserAgent: r.UserAgent(),
+			ArtistID:  router.Param(r, "id"),
+			RequestID: RequestIDFrom(r),
+		}
+		writeLog(entry, start)
+	})
+}
+
+func writeLog(entry logEntry, start time.Time) {
+	if os.Getenv("LOG_FORMAT") == "json" {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			logf("middleware: failed to marshal log entry: %v", err)
+			return
+		}
+		logf("%s", string(data))
+		return
+	}
+
+	logf("[%s] %s %s - %d %dB (%dms) %s (%s) artist=%s %s",
+		start.Format("2006-01-02 15:04:05"), entry.Method, entry.Path, entry.Status, entry.Bytes, entry.ElapsedMs,
+		entry.RemoteIP, entry.UserAgent, entry.ArtistID, entry.RequestID)
+}
+
+// Recover wraps next, turning any panic into a 500 response via
+// views.RenderError instead of crashing the process. If next had already
+// written a status code (or body) before panicking, the response is beyond
+// repair, so Recover only logs rather than writing a second, overlapping
+// response on top of it.
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := &responseWriter{ResponseWriter: w}
+		defer func() {
+			if err := recover(); err != nil {
+				logf("middleware: recovered panic on %s %s: %v", r.Method, r.URL.Path, err)
+				if rw.status != 0 {
+					return
+				}
+				views.RenderError(w, http.StatusInternalServerError, "Something went wrong")
+			}
+		}()
+		next.ServeHTTP(rw, r)
+	})
+}
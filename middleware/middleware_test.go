@@ -0,0 +1,197 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"music-explorer/views"
+)
+
+func TestSetLoggerReplacesSink(t *testing.T) {
+	var got string
+	SetLogger(func(format string, args ...interface{}) {
+		got += format
+	})
+	t.Cleanup(func() { SetLogger(log.Printf) })
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	Logger(next).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if got == "" {
+		t.Error("custom logger was never called; Logger should route through the pluggable sink")
+	}
+}
+
+func TestLoggerCapturesStatus(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+		w.Write([]byte("ok"))
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/artist/1", nil)
+	Logger(next).ServeHTTP(w, r)
+
+	if w.Code != http.StatusTeapot {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusTeapot)
+	}
+}
+
+func TestRecoverTurnsPanicIntoInternalServerError(t *testing.T) {
+	if err := views.Init(false); err != nil {
+		t.Fatalf("views.Init: %v", err)
+	}
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	Recover(next).ServeHTTP(w, r)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+}
+
+func TestRecoverDoesNotDoubleWriteAfterPartialOutput(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		panic("boom after headers sent")
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	Recover(next).ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d (the panic happened after headers were already sent)", w.Code, http.StatusOK)
+	}
+}
+
+func TestRequestIDGeneratesAndEchoesHeader(t *testing.T) {
+	var seen string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = RequestIDFrom(r)
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	RequestID(next).ServeHTTP(w, r)
+
+	if seen == "" {
+		t.Fatal("RequestIDFrom returned empty inside handler")
+	}
+	if got := w.Header().Get("X-Request-ID"); got != seen {
+		t.Errorf("X-Request-ID header = %q, want %q", got, seen)
+	}
+}
+
+func TestRequestIDReusesInboundHeader(t *testing.T) {
+	var seen string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = RequestIDFrom(r)
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("X-Request-ID", "inbound-id")
+	RequestID(next).ServeHTTP(w, r)
+
+	if seen != "inbound-id" {
+		t.Errorf("RequestIDFrom = %q, want %q (inbound header should be reused)", seen, "inbound-id")
+	}
+}
+
+func TestGzipCompressesWhenAccepted(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello world"))
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	Gzip(next).ServeHTTP(w, r)
+
+	if w.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("Content-Encoding = %q, want gzip", w.Header().Get("Content-Encoding"))
+	}
+	gr, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatalf("gzip.NewReader: %v", err)
+	}
+	body, err := io.ReadAll(gr)
+	if err != nil {
+		t.Fatalf("reading gzip body: %v", err)
+	}
+	if string(body) != "hello world" {
+		t.Errorf("decompressed body = %q, want %q", body, "hello world")
+	}
+}
+
+func TestGzipSkipsWhenNotAccepted(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello world"))
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	Gzip(next).ServeHTTP(w, r)
+
+	if w.Header().Get("Content-Encoding") == "gzip" {
+		t.Fatal("Content-Encoding should not be gzip when client didn't request it")
+	}
+	if w.Body.String() != "hello world" {
+		t.Errorf("body = %q, want uncompressed %q", w.Body.String(), "hello world")
+	}
+}
+
+func TestRateLimiterAllowsBurstThenRejects(t *testing.T) {
+	rl := NewRateLimiter(0, 2)
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := rl.Limit(next)
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "203.0.113.1:54321"
+
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if w.Code != http.StatusOK {
+			t.Fatalf("request %d: status = %d, want 200 (within burst)", i, w.Code)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Code != http.StatusTooManyRequests {
+		t.Errorf("status = %d, want %d (burst exhausted, no refill rate)", w.Code, http.StatusTooManyRequests)
+	}
+}
+
+func TestRateLimiterTracksIPsSeparately(t *testing.T) {
+	rl := NewRateLimiter(0, 1)
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := rl.Limit(next)
+
+	for _, ip := range []string{"203.0.113.1:1", "203.0.113.2:1"} {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = ip
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if w.Code != http.StatusOK {
+			t.Errorf("ip %s: status = %d, want 200 (separate bucket)", ip, w.Code)
+		}
+	}
+}
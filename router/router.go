@@ -0,0 +1,202 @@
+// Package router provides a small http.ServeMux-style router with typed path
+// parameters, per-method registration, and a composable middleware chain. It
+// replaces hand-rolled strings.Split path parsing and ad-hoc method checks
+// scattered across handlers.
+package router
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Middleware wraps a handler to produce another handler, e.g. for logging,
+// panic recovery, or rate limiting.
+type Middleware func(http.Handler) http.Handler
+
+type paramsKey struct{}
+
+// route is a single registered pattern for one HTTP method.
+type route struct {
+	method   string
+	segments []string
+	handler  http.HandlerFunc
+}
+
+// Router dispatches requests to handlers registered by method and path
+// pattern, running a shared middleware chain around every match.
+type Router struct {
+	routes      []route
+	exact       map[string]http.HandlerFunc
+	middlewares []Middleware
+	notFound    http.HandlerFunc
+}
+
+// New creates an empty Router. notFound is used when no route matches; if
+// nil, http.NotFound is used.
+func New(notFound http.HandlerFunc) *Router {
+	if notFound == nil {
+		notFound = http.NotFound
+	}
+	return &Router{exact: make(map[string]http.HandlerFunc), notFound: notFound}
+}
+
+// Use appends middleware to the chain applied to every request.
+func (rt *Router) Use(mw ...Middleware) {
+	rt.middlewares = append(rt.middlewares, mw...)
+}
+
+// GET registers a handler for GET requests matching pattern, e.g. "/artist/{id}".
+func (rt *Router) GET(pattern string, handler http.HandlerFunc) {
+	rt.handle(http.MethodGet, pattern, handler)
+}
+
+// POST registers a handler for POST requests matching pattern.
+func (rt *Router) POST(pattern string, handler http.HandlerFunc) {
+	rt.handle(http.MethodPost, pattern, handler)
+}
+
+// HandleExact registers a handler that only matches path exactly, with no
+// parameters and no method restriction beyond what the handler itself
+// enforces. This is for routes like "/" where any trailing segment is a 404
+// rather than a candidate for parameter matching.
+func (rt *Router) HandleExact(path string, handler http.HandlerFunc) {
+	rt.exact[path] = handler
+}
+
+func (rt *Router) handle(method, pattern string, handler http.HandlerFunc) {
+	rt.routes = append(rt.routes, route{
+		method:   method,
+		segments: strings.Split(strings.Trim(pattern, "/"), "/"),
+		handler:  handler,
+	})
+}
+
+// ServeHTTP implements http.Handler. It resolves the matching route first -
+// attaching any path params to the request's context - and only then runs
+// the middleware chain around the matched handler, so middleware such as
+// middleware.Logger can see params via Param/IntParam.
+func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	handler, r := rt.resolve(r)
+	var h http.Handler = handler
+	for i := len(rt.middlewares) - 1; i >= 0; i-- {
+		h = rt.middlewares[i](h)
+	}
+	h.ServeHTTP(w, r)
+}
+
+// resolve finds the handler for r and returns the request with any matched
+// path params attached to its context.
+func (rt *Router) resolve(r *http.Request) (http.HandlerFunc, *http.Request) {
+	if handler, ok := rt.exact[r.URL.Path]; ok {
+		return handler, r
+	}
+
+	reqSegments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+	methodMismatch := false
+	for _, rte := range rt.routes {
+		params, ok := match(rte.segments, reqSegments)
+		if !ok {
+			continue
+		}
+		if rte.method != r.Method {
+			methodMismatch = true
+			continue
+		}
+		return rte.handler, r.WithContext(context.WithValue(r.Context(), paramsKey{}, params))
+	}
+
+	if methodMismatch {
+		return func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "Wrong method", http.StatusMethodNotAllowed)
+		}, r
+	}
+	return rt.notFound, r
+}
+
+// match reports whether reqSegments satisfies the pattern segments, and if
+// so, the named parameters extracted from "{name}" segments.
+func match(pattern, reqSegments []string) (map[string]string, bool) {
+	if len(pattern) != len(reqSegments) {
+		return nil, false
+	}
+	params := make(map[string]string)
+	for i, seg := range pattern {
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			params[strings.Trim(seg, "{}")] = reqSegments[i]
+			continue
+		}
+		if seg != reqSegments[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// Param returns the named path parameter extracted for this request, or ""
+// if it was not part of the matched route.
+func Param(r *http.Request, name string) string {
+	params, _ := r.Context().Value(paramsKey{}).(map[string]string)
+	return params[name]
+}
+
+// IntParam returns the named path parameter parsed as an int. ok is false if
+// the parameter is absent or not a valid integer.
+func IntParam(r *http.Request, name string) (value int, ok bool) {
+	v, err := strconv.Atoi(Param(r, name))
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
+
+// QueryParam declares how a single query parameter should be validated by
+// ValidateQuery: Required rejects an empty value, and a non-empty Allowed
+// restricts the value to that set.
+type QueryParam struct {
+	Name     string
+	Required bool
+	Allowed  []string
+}
+
+// ValidateQuery checks r's query string against a declarative list of
+// QueryParam rules, replacing the nested if-chains handlers used to grow for
+// each new query parameter. It returns the first violation found, formatted
+// for direct use as an error message.
+func ValidateQuery(r *http.Request, params ...QueryParam) error {
+	for _, p := range params {
+		value := r.URL.Query().Get(p.Name)
+		if value == "" {
+			if p.Required {
+				return &QueryError{Param: p.Name, Message: "is required"}
+			}
+			continue
+		}
+		if len(p.Allowed) == 0 {
+			continue
+		}
+		allowed := false
+		for _, a := range p.Allowed {
+			if value == a {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return &QueryError{Param: p.Name, Message: "must be one of " + strings.Join(p.Allowed, ", ")}
+		}
+	}
+	return nil
+}
+
+// QueryError describes a single query-parameter validation failure.
+type QueryError struct {
+	Param   string
+	Message string
+}
+
+func (e *QueryError) Error() string {
+	return e.Param + " " + e.Message
+}
@@ -0,0 +1,116 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResolveExtractsPathParams(t *testing.T) {
+	rt := New(nil)
+	rt.GET("/artist/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id, ok := IntParam(r, "id")
+		if !ok {
+			t.Errorf("IntParam(id) not ok")
+		}
+		if id != 42 {
+			t.Errorf("IntParam(id) = %d, want 42", id)
+		}
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/artist/42", nil)
+	rt.ServeHTTP(w, r)
+}
+
+func TestResolveWrongMethodIsMethodNotAllowed(t *testing.T) {
+	rt := New(nil)
+	rt.GET("/artist/{id}", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("handler should not run for a method mismatch")
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/artist/42", nil)
+	rt.ServeHTTP(w, r)
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestResolveUnmatchedPathUsesNotFound(t *testing.T) {
+	rt := New(nil)
+	rt.GET("/artist/{id}", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("handler should not run for an unmatched path")
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/nope", nil)
+	rt.ServeHTTP(w, r)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestHandleExactOnlyMatchesExactPath(t *testing.T) {
+	rt := New(nil)
+	rt.HandleExact("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	rt.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("GET /: status = %d, want 200", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	rt.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/other", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("GET /other: status = %d, want 404", w.Code)
+	}
+}
+
+func TestMiddlewareSeesResolvedParams(t *testing.T) {
+	rt := New(nil)
+	var sawID string
+	rt.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			sawID = Param(r, "id")
+			next.ServeHTTP(w, r)
+		})
+	})
+	rt.GET("/artist/{id}", func(w http.ResponseWriter, r *http.Request) {})
+
+	rt.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/artist/7", nil))
+
+	if sawID != "7" {
+		t.Errorf("middleware saw Param(id) = %q, want %q", sawID, "7")
+	}
+}
+
+func TestValidateQueryRequiredAndAllowed(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		params  []QueryParam
+		wantErr bool
+	}{
+		{"missing required", "", []QueryParam{{Name: "q", Required: true}}, true},
+		{"present required", "q=hello", []QueryParam{{Name: "q", Required: true}}, false},
+		{"disallowed value", "section=bogus", []QueryParam{{Name: "section", Allowed: []string{"dates", "locations"}}}, true},
+		{"allowed value", "section=dates", []QueryParam{{Name: "section", Allowed: []string{"dates", "locations"}}}, false},
+		{"optional and absent", "", []QueryParam{{Name: "section", Allowed: []string{"dates"}}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/?"+tt.query, nil)
+			err := ValidateQuery(r, tt.params...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateQuery() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
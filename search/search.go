@@ -0,0 +1,154 @@
+// Package search implements a unified, ranked search over artist data,
+// replacing the two parallel half-implementations that used to live in
+// handlers (one matching only FirstAlbum/CreationDate, the other matching
+// name/member/location/date against a different query param).
+package search
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Field names used as Result.Type and as keys in Weights.
+const (
+	TypeArtist       = "artist"
+	TypeMember       = "member"
+	TypeLocation     = "location"
+	TypeFirstAlbum   = "first_album"
+	TypeCreationDate = "creation_date"
+	TypeConcertDate  = "concert_date"
+)
+
+// Artist is the subset of the handlers' Artist type that search needs. It
+// mirrors the upstream API shape field by field, but it is a distinct type:
+// callers convert their artist slice into []Artist before searching.
+type Artist struct {
+	ID           int
+	Name         string
+	Members      []string
+	Locations    string
+	ConcertDates string
+	FirstAlbum   string
+	CreationDate int
+}
+
+// Result is a single ranked match against a field of one artist.
+type Result struct {
+	ArtistID int     `json:"artistId"`
+	Name     string  `json:"name"`
+	Type     string  `json:"type"`
+	Field    string  `json:"field"`
+	Score    float64 `json:"-"`
+}
+
+// Response is the envelope returned by /api/search: a bare array is not
+// forward-compatible, so results are always wrapped with a total count.
+type Response struct {
+	Results []Result `json:"results"`
+	Total   int      `json:"total"`
+}
+
+// Weights assigns relative importance per field; fields absent from the map
+// default to 1.0. Exact/prefix/substring match quality is applied on top of
+// the field weight.
+type Weights map[string]float64
+
+// DefaultWeights favours the artist's own name over its members, and
+// members over location/date metadata.
+func DefaultWeights() Weights {
+	return Weights{
+		TypeArtist:       3,
+		TypeMember:       2,
+		TypeLocation:     1,
+		TypeFirstAlbum:   1,
+		TypeCreationDate: 1,
+		TypeConcertDate:  1,
+	}
+}
+
+// Options controls ranking and pagination for Search.
+type Options struct {
+	Weights Weights
+	Limit   int
+	Offset  int
+}
+
+// Search matches query against every artist's name, members, locations,
+// first album and creation/concert dates, ranking exact matches above
+// prefix matches above substring matches, weighted per field.
+func Search(artists []Artist, query string, opts Options) Response {
+	weights := opts.Weights
+	if weights == nil {
+		weights = DefaultWeights()
+	}
+	query = strings.ToLower(strings.TrimSpace(query))
+
+	var results []Result
+	add := func(artist Artist, value, fieldType string) {
+		score := matchScore(strings.ToLower(value), query)
+		if score <= 0 {
+			return
+		}
+		if w, ok := weights[fieldType]; ok {
+			score *= w
+		}
+		results = append(results, Result{
+			ArtistID: artist.ID,
+			Name:     artist.Name,
+			Type:     fieldType,
+			Field:    value,
+			Score:    score,
+		})
+	}
+
+	for _, artist := range artists {
+		add(artist, artist.Name, TypeArtist)
+		for _, member := range artist.Members {
+			add(artist, member, TypeMember)
+		}
+		add(artist, artist.Locations, TypeLocation)
+		add(artist, artist.FirstAlbum, TypeFirstAlbum)
+		add(artist, artist.ConcertDates, TypeConcertDate)
+		add(artist, strconv.Itoa(artist.CreationDate), TypeCreationDate)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+
+	total := len(results)
+	results = paginate(results, opts.Offset, opts.Limit)
+
+	return Response{Results: results, Total: total}
+}
+
+// matchScore returns 0 for no match, and otherwise a score where an exact
+// match beats a prefix match beats a substring match.
+func matchScore(value, query string) float64 {
+	if query == "" || value == "" {
+		return 0
+	}
+	switch {
+	case value == query:
+		return 3
+	case strings.HasPrefix(value, query):
+		return 2
+	case strings.Contains(value, query):
+		return 1
+	default:
+		return 0
+	}
+}
+
+func paginate(results []Result, offset, limit int) []Result {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(results) {
+		return []Result{}
+	}
+	results = results[offset:]
+	if limit > 0 && limit < len(results) {
+		results = results[:limit]
+	}
+	return results
+}
@@ -0,0 +1,51 @@
+package search
+
+import "testing"
+
+func TestSearchRanksExactPrefixSubstring(t *testing.T) {
+	artists := []Artist{
+		{ID: 1, Name: "Queen"},
+		{ID: 2, Name: "Queens of the Stone Age"},
+		{ID: 3, Name: "The Queenstown Five"},
+	}
+
+	resp := Search(artists, "Queen", Options{})
+
+	if resp.Total != 3 {
+		t.Fatalf("Total = %d, want 3", resp.Total)
+	}
+	if resp.Results[0].ArtistID != 1 {
+		t.Errorf("top result = artist %d, want exact match (artist 1) first", resp.Results[0].ArtistID)
+	}
+}
+
+func TestSearchPagination(t *testing.T) {
+	artists := []Artist{
+		{ID: 1, Name: "Queen"},
+		{ID: 2, Name: "Queens of the Stone Age"},
+		{ID: 3, Name: "The Queenstown Five"},
+	}
+
+	resp := Search(artists, "Queen", Options{Limit: 1, Offset: 1})
+
+	if resp.Total != 3 {
+		t.Errorf("Total = %d, want 3 (unaffected by pagination)", resp.Total)
+	}
+	if len(resp.Results) != 1 {
+		t.Fatalf("len(Results) = %d, want 1", len(resp.Results))
+	}
+}
+
+func TestBuildTrieIndexesAllFields(t *testing.T) {
+	artists := []Artist{
+		{ID: 1, Name: "Queen", Members: []string{"Freddie Mercury"}, Locations: "London", FirstAlbum: "Queen", ConcertDates: "16-12-2019", CreationDate: 1970},
+	}
+
+	trie := BuildTrie(artists)
+
+	for _, prefix := range []string{"que", "freddie", "lond", "16-12", "1970"} {
+		if got := trie.PrefixSearch(prefix, 0); len(got) == 0 {
+			t.Errorf("PrefixSearch(%q) = empty, want at least one match", prefix)
+		}
+	}
+}
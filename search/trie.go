@@ -0,0 +1,105 @@
+package search
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Suggestion is one autocomplete candidate surfaced by Trie.PrefixSearch.
+type Suggestion struct {
+	Text     string `json:"text"`
+	Type     string `json:"type"`
+	ArtistID int    `json:"artistId"`
+}
+
+type trieNode struct {
+	children    map[rune]*trieNode
+	suggestions []Suggestion
+}
+
+func newTrieNode() *trieNode {
+	return &trieNode{children: make(map[rune]*trieNode)}
+}
+
+// Trie is an in-memory prefix tree over artist names, members, locations and
+// dates, giving O(prefix length) autocomplete lookups instead of scanning
+// every artist on every keystroke.
+type Trie struct {
+	root *trieNode
+}
+
+// NewTrie returns an empty Trie.
+func NewTrie() *Trie {
+	return &Trie{root: newTrieNode()}
+}
+
+// Insert adds text to the trie, associated with the given suggestion.
+func (t *Trie) Insert(text string, suggestion Suggestion) {
+	node := t.root
+	for _, r := range strings.ToLower(text) {
+		child, ok := node.children[r]
+		if !ok {
+			child = newTrieNode()
+			node.children[r] = child
+		}
+		node = child
+	}
+	node.suggestions = append(node.suggestions, suggestion)
+}
+
+// PrefixSearch returns up to limit suggestions whose text starts with
+// prefix. If limit is <= 0, all matches are returned.
+func (t *Trie) PrefixSearch(prefix string, limit int) []Suggestion {
+	node := t.root
+	for _, r := range strings.ToLower(prefix) {
+		child, ok := node.children[r]
+		if !ok {
+			return nil
+		}
+		node = child
+	}
+
+	var out []Suggestion
+	collect(node, &out, limit)
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
+
+func collect(node *trieNode, out *[]Suggestion, limit int) {
+	if limit > 0 && len(*out) >= limit {
+		return
+	}
+	*out = append(*out, node.suggestions...)
+	for _, child := range node.children {
+		if limit > 0 && len(*out) >= limit {
+			return
+		}
+		collect(child, out, limit)
+	}
+}
+
+// BuildTrie constructs a fresh Trie over every artist's name, members,
+// locations and dates. Callers rebuild it whenever the underlying artist
+// cache refreshes.
+func BuildTrie(artists []Artist) *Trie {
+	t := NewTrie()
+	for _, artist := range artists {
+		t.Insert(artist.Name, Suggestion{Text: artist.Name, Type: TypeArtist, ArtistID: artist.ID})
+		for _, member := range artist.Members {
+			t.Insert(member, Suggestion{Text: member, Type: TypeMember, ArtistID: artist.ID})
+		}
+		if artist.Locations != "" {
+			t.Insert(artist.Locations, Suggestion{Text: artist.Locations, Type: TypeLocation, ArtistID: artist.ID})
+		}
+		if artist.FirstAlbum != "" {
+			t.Insert(artist.FirstAlbum, Suggestion{Text: artist.FirstAlbum, Type: TypeFirstAlbum, ArtistID: artist.ID})
+		}
+		if artist.ConcertDates != "" {
+			t.Insert(artist.ConcertDates, Suggestion{Text: artist.ConcertDates, Type: TypeConcertDate, ArtistID: artist.ID})
+		}
+		t.Insert(strconv.Itoa(artist.CreationDate), Suggestion{Text: strconv.Itoa(artist.CreationDate), Type: TypeCreationDate, ArtistID: artist.ID})
+	}
+	return t
+}
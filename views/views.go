@@ -0,0 +1,117 @@
+// Package views owns HTML template parsing and rendering for the
+// application. Templates are embedded into the binary at build time so the
+// binary can be deployed without a template/ directory next to it, and are
+// parsed once at startup instead of on every request.
+package views
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+//go:embed template/*.html
+var templateFS embed.FS
+
+// pageNames lists every page template, each parsed together with the shared
+// funcMap. Page content lives at template/<name>.html.
+var pageNames = []string{"home", "artists", "artist", "error"}
+
+// funcs are the template helpers available to every page, registered
+// centrally so templates stop doing their own string manipulation.
+var funcs = template.FuncMap{
+	"lower": strings.ToLower,
+	"formatDate": func(date string) string {
+		return strings.ReplaceAll(date, "-", "/")
+	},
+	"join": strings.Join,
+}
+
+var (
+	mu      sync.RWMutex
+	pages   = make(map[string]*template.Template)
+	devMode bool
+)
+
+// Init parses every page template once. When dev is true, Render and
+// RenderBlock instead re-parse the template from disk on every call, so
+// template edits show up without a rebuild (mirrors pkgsite's frontend
+// Server devMode).
+func Init(dev bool) error {
+	devMode = dev
+	if dev {
+		return nil
+	}
+	for _, name := range pageNames {
+		tmpl, err := parse(name)
+		if err != nil {
+			return fmt.Errorf("views: parsing %q: %w", name, err)
+		}
+		mu.Lock()
+		pages[name] = tmpl
+		mu.Unlock()
+	}
+	return nil
+}
+
+// parse parses template/<name>.html, rooted under the template set's name
+// matching the file's basename ("<name>.html") - ParseFiles/ParseFS name
+// templates after the file they come from, so the root template must use
+// that same name or it's left empty while a differently-named template ends
+// up populated in the set instead.
+func parse(name string) (*template.Template, error) {
+	if devMode {
+		return template.New(name + ".html").Funcs(funcs).ParseFiles("views/template/" + name + ".html")
+	}
+	return template.New(name + ".html").Funcs(funcs).ParseFS(templateFS, "template/"+name+".html")
+}
+
+func templateFor(name string) (*template.Template, error) {
+	if devMode {
+		return parse(name)
+	}
+	mu.RLock()
+	tmpl, ok := pages[name]
+	mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("views: unknown page %q", name)
+	}
+	return tmpl, nil
+}
+
+// Render executes the named page template with data.
+func Render(w http.ResponseWriter, name string, data interface{}) error {
+	tmpl, err := templateFor(name)
+	if err != nil {
+		return err
+	}
+	return tmpl.ExecuteTemplate(w, name+".html", data)
+}
+
+// RenderBlock executes a single named block within page (e.g. the
+// "locations" block inside artist.html), for HTMX partial-swap responses.
+func RenderBlock(w http.ResponseWriter, page, block string, data interface{}) error {
+	tmpl, err := templateFor(page)
+	if err != nil {
+		return err
+	}
+	return tmpl.ExecuteTemplate(w, block, data)
+}
+
+// RenderError renders the shared error page with the given status and
+// message, logging if the template itself fails to execute.
+func RenderError(w http.ResponseWriter, status int, message string) {
+	w.WriteHeader(status)
+	err := Render(w, "error", struct {
+		Code    int
+		Message string
+	}{Code: status, Message: message})
+	if err != nil {
+		log.Printf("views: error rendering error page: %v", err)
+		fmt.Fprintf(w, "Error %d: %s", status, message)
+	}
+}
@@ -0,0 +1,64 @@
+package views
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRenderEveryPage(t *testing.T) {
+	if err := Init(false); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+
+	type artist struct {
+		ID           int
+		Name         string
+		Image        string
+		Members      []string
+		FirstAlbum   string
+		CreationDate int
+	}
+	type errorData struct {
+		Code    int
+		Message string
+	}
+	type artistData struct {
+		Artist    artist
+		Locations struct{ Locations string }
+		Dates     struct{ Dates string }
+		Relations struct{ DatesLocations string }
+	}
+	fixtures := map[string]interface{}{
+		"home":    nil,
+		"artists": []artist{{ID: 1, Name: "Queen", CreationDate: 1970}},
+		"error":   errorData{Code: 500, Message: "boom"},
+		"artist":  artistData{Artist: artist{ID: 1, Name: "Queen"}},
+	}
+
+	for _, name := range pageNames {
+		w := httptest.NewRecorder()
+		if err := Render(w, name, fixtures[name]); err != nil {
+			t.Errorf("Render(%q): %v", name, err)
+		}
+		if w.Body.Len() == 0 {
+			t.Errorf("Render(%q) wrote no output", name)
+		}
+	}
+}
+
+func TestRenderErrorWritesStatusAndMessage(t *testing.T) {
+	if err := Init(false); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	RenderError(w, 404, "not found")
+
+	if w.Code != 404 {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "not found") {
+		t.Errorf("body = %q, want it to contain %q", w.Body.String(), "not found")
+	}
+}